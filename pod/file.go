@@ -0,0 +1,146 @@
+package pod
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// UserFileSourceRef points a UserFile at a key of a configMap- or
+// secret-like source registered on the pod via UserPod.Sources.
+type UserFileSourceRef struct {
+	Kind string `json:"kind"` // "configMap" or "secret"
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// UserFileSource registers where the keys of a named configMap or secret
+// can be resolved from: a directory holding one file per key, or a base URL
+// with the key appended.
+type UserFileSource struct {
+	Kind string `json:"kind"` // "configMap" or "secret"
+	Name string `json:"name"`
+	Dir  string `json:"dir,omitempty"`
+	Url  string `json:"url,omitempty"`
+}
+
+// Resolve finds the source registered for ref's kind and name, if any.
+func (pod *UserPod) Resolve(ref UserFileSourceRef) (*UserFileSource, bool) {
+	for i := range pod.Sources {
+		s := pod.Sources[i]
+		if s.Kind == ref.Kind && s.Name == ref.Name {
+			return &s, true
+		}
+	}
+	return nil, false
+}
+
+// Load resolves f's contents: a SourceRef is read from its registered
+// directory or, if the source has no Dir, fetched from its base Url with the
+// key appended; otherwise f's inline Contents is used. When f.Encoding is
+// "template", the result is expanded as a Go template over the pod's own
+// declared container environment variables (under .Env) and the pod's own
+// metadata (under .Pod).
+func (pod *UserPod) Load(f UserFile) (string, error) {
+	content := f.Contents
+
+	if f.SourceRef != nil {
+		src, ok := pod.Resolve(*f.SourceRef)
+		if !ok {
+			return "", fmt.Errorf("hyper: file %s: no source registered for %s %q", f.Name, f.SourceRef.Kind, f.SourceRef.Name)
+		}
+		body, err := loadSourceKey(src, f.SourceRef.Key)
+		if err != nil {
+			return "", fmt.Errorf("hyper: file %s: %v", f.Name, err)
+		}
+		content = string(body)
+	}
+
+	if f.Encoding != "template" {
+		return content, nil
+	}
+
+	return renderTemplate(f.Name, content, pod)
+}
+
+// loadSourceKey reads key from src: from its Dir if set, else fetched from
+// its Url with the key appended.
+func loadSourceKey(src *UserFileSource, key string) ([]byte, error) {
+	if src.Dir != "" {
+		return ioutil.ReadFile(filepath.Join(src.Dir, key))
+	}
+	if src.Url != "" {
+		resp, err := http.Get(strings.TrimSuffix(src.Url, "/") + "/" + key)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetching %s %q key %q: unexpected status %s", src.Kind, src.Name, key, resp.Status)
+		}
+		return ioutil.ReadAll(resp.Body)
+	}
+	return nil, fmt.Errorf("source %s %q has neither a dir nor a url to read %q from", src.Kind, src.Name, key)
+}
+
+// renderTemplate expands tpl as a Go template, exposing the pod's own
+// declared container environment variables under .Env and the pod's own
+// metadata under .Pod. It never sees hyperd's own process environment, so a
+// pod file can't use a template to exfiltrate it.
+func renderTemplate(name, tpl string, pod *UserPod) (string, error) {
+	t, err := template.New(name).Parse(tpl)
+	if err != nil {
+		return "", err
+	}
+
+	env := map[string]string{}
+	for _, c := range pod.Containers {
+		for _, e := range c.Envs {
+			env[e.Env] = e.Value
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, map[string]interface{}{"Env": env, "Pod": pod}); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// validateSources checks that every registered UserFileSource can actually
+// resolve a key (has a Dir or a Url) and that every UserFile's SourceRef, if
+// any, resolves to one of them.
+func (pod *UserPod) validateSources() error {
+	for _, s := range pod.Sources {
+		if s.Dir == "" && s.Url == "" {
+			return fmt.Errorf("hyper: source %s %q must set a dir or a url", s.Kind, s.Name)
+		}
+	}
+
+	for _, f := range pod.Files {
+		if f.SourceRef == nil {
+			continue
+		}
+		if _, ok := pod.Resolve(*f.SourceRef); !ok {
+			return fmt.Errorf("hyper: file %s references %s %q, which is not registered in the pod's sources", f.Name, f.SourceRef.Kind, f.SourceRef.Name)
+		}
+	}
+	return nil
+}
+
+// filePerm returns the effective permission for a container's reference to
+// file f: an explicit Perm, or else "0400" for a secret-sourced file and
+// "0755" otherwise.
+func filePerm(ref UserFileReference, f UserFile) string {
+	if ref.Perm != "" {
+		return ref.Perm
+	}
+	if f.SourceRef != nil && f.SourceRef.Kind == "secret" {
+		return "0400"
+	}
+	return "0755"
+}
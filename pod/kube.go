@@ -0,0 +1,458 @@
+package pod
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	kubeyaml "sigs.k8s.io/yaml"
+)
+
+// ProcessKubePodFile reads a Kubernetes Pod (or PodTemplateSpec) manifest
+// from podFile, in either YAML or JSON, and translates it into a UserPod.
+// This lets users hand hyper a standard kubectl-style manifest instead of
+// the hyper-specific POD JSON.
+func ProcessKubePodFile(podFile string) (*UserPod, error) {
+	if _, err := os.Stat(podFile); err != nil && os.IsNotExist(err) {
+		return nil, err
+	}
+	body, err := ioutil.ReadFile(podFile)
+	if err != nil {
+		return nil, err
+	}
+	return ProcessKubePodBytes(body)
+}
+
+// ProcessKubePodBytes decodes a Kubernetes Pod manifest (YAML or JSON) and
+// converts it into a UserPod, mirroring ProcessPodBytes for hyper's native
+// POD format.
+func ProcessKubePodBytes(body []byte) (*UserPod, error) {
+	var kubePod v1.Pod
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(body), len(body))
+	if err := decoder.Decode(&kubePod); err != nil {
+		return nil, err
+	}
+
+	userPod, err := kubePodSpecToUserPod(kubePod.ObjectMeta.Name, &kubePod.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := userPod.Validate(); err != nil {
+		return nil, err
+	}
+
+	return userPod, nil
+}
+
+// kubePodSpecToUserPod walks a v1.PodSpec and builds the equivalent UserPod.
+func kubePodSpecToUserPod(name string, spec *v1.PodSpec) (*UserPod, error) {
+	userPod := &UserPod{
+		Name: name,
+		Type: "pod",
+	}
+
+	for _, v := range spec.Volumes {
+		vol, err := kubeVolumeToUserVolume(v)
+		if err != nil {
+			return nil, err
+		}
+		userPod.Volumes = append(userPod.Volumes, *vol)
+	}
+
+	var sumCPU, sumMem int64
+	for _, c := range spec.Containers {
+		container, err := kubeContainerToUserContainer(c)
+		if err != nil {
+			return nil, err
+		}
+		userPod.Containers = append(userPod.Containers, *container)
+
+		if cpu, err := container.Resources.CPU.requestMillicores(); err == nil {
+			sumCPU += cpu
+		}
+		if mem, err := container.Resources.Memory.requestBytes(); err == nil {
+			sumMem += mem
+		}
+	}
+	if sumCPU > 0 {
+		userPod.Resource.CPU = ResourceCPUSpec{Requests: fmt.Sprintf("%dm", sumCPU), Limits: fmt.Sprintf("%dm", sumCPU)}
+	}
+	if sumMem > 0 {
+		userPod.Resource.Memory = ResourceMemorySpec{Requests: strconv.FormatInt(sumMem, 10), Limits: strconv.FormatInt(sumMem, 10)}
+	}
+
+	if userPod.Name == "" {
+		userPod.Name = RandStr(10, "alphanum")
+	}
+
+	return userPod, nil
+}
+
+// kubeVolumeToUserVolume maps a v1.Volume to the equivalent UserVolume.
+// configMap and secret volumes project their keys as a directory, same as
+// Kubernetes: with no Items they mount everything the source holds, which
+// UserVolume's ConfigMap/Secret sources leave to whatever materializes them
+// at run time, so no key enumeration is needed here.
+func kubeVolumeToUserVolume(v v1.Volume) (*UserVolume, error) {
+	switch {
+	case v.HostPath != nil:
+		return &UserVolume{Name: v.Name, HostPath: &VolumeHostPathSource{Path: v.HostPath.Path}}, nil
+	case v.EmptyDir != nil:
+		medium := ""
+		if v.EmptyDir.Medium == v1.StorageMediumMemory {
+			medium = "Memory"
+		}
+		return &UserVolume{Name: v.Name, EmptyDir: &VolumeEmptyDirSource{Medium: medium}}, nil
+	case v.PersistentVolumeClaim != nil:
+		return &UserVolume{Name: v.Name, PersistentVolumeClaim: &VolumePersistentVolumeClaimSource{
+			ClaimName: v.PersistentVolumeClaim.ClaimName,
+			ReadOnly:  v.PersistentVolumeClaim.ReadOnly,
+		}}, nil
+	case v.ConfigMap != nil:
+		return &UserVolume{Name: v.Name, ConfigMap: &VolumeConfigMapSource{
+			Name: v.ConfigMap.Name, Items: kubeKeyToPathsToUserItems(v.ConfigMap.Items),
+		}}, nil
+	case v.Secret != nil:
+		return &UserVolume{Name: v.Name, Secret: &VolumeSecretSource{
+			SecretName: v.Secret.SecretName, Items: kubeKeyToPathsToUserItems(v.Secret.Items),
+		}}, nil
+	case v.NFS != nil:
+		return &UserVolume{Name: v.Name, NFS: &VolumeNFSSource{
+			Server: v.NFS.Server, Path: v.NFS.Path, ReadOnly: v.NFS.ReadOnly,
+		}}, nil
+	case v.ISCSI != nil:
+		return &UserVolume{Name: v.Name, ISCSI: &VolumeISCSISource{
+			TargetPortal: v.ISCSI.TargetPortal, Iqn: v.ISCSI.IQN, Lun: int(v.ISCSI.Lun),
+			FSType: v.ISCSI.FSType, ReadOnly: v.ISCSI.ReadOnly,
+		}}, nil
+	case v.RBD != nil:
+		return &UserVolume{Name: v.Name, RBD: &VolumeRBDSource{
+			CephMonitors: v.RBD.CephMonitors, RBDImage: v.RBD.RBDImage, RBDPool: v.RBD.RBDPool,
+			RadosUser: v.RBD.RadosUser, Keyring: v.RBD.Keyring, FSType: v.RBD.FSType, ReadOnly: v.RBD.ReadOnly,
+		}}, nil
+	case v.CephFS != nil:
+		return &UserVolume{Name: v.Name, CephFS: &VolumeCephFSSource{
+			Monitors: v.CephFS.Monitors, Path: v.CephFS.Path, User: v.CephFS.User,
+			SecretFile: v.CephFS.SecretFile, ReadOnly: v.CephFS.ReadOnly,
+		}}, nil
+	}
+	return nil, fmt.Errorf("hyper: unsupported volume source for volume %q", v.Name)
+}
+
+// kubeKeyToPathsToUserItems maps a configMap/secret volume's Items; a nil
+// slice (project every key) round-trips as nil.
+func kubeKeyToPathsToUserItems(items []v1.KeyToPath) []VolumeKeyToPath {
+	var out []VolumeKeyToPath
+	for _, item := range items {
+		out = append(out, VolumeKeyToPath{Key: item.Key, Path: item.Path})
+	}
+	return out
+}
+
+// kubeContainerToUserContainer maps a v1.Container to a UserContainer. Note
+// that, as in Kubernetes, Command overrides the image Entrypoint and Args
+// overrides the image Cmd.
+func kubeContainerToUserContainer(c v1.Container) (*UserContainer, error) {
+	container := &UserContainer{
+		Name:       c.Name,
+		Image:      c.Image,
+		Entrypoint: c.Command,
+		Command:    c.Args,
+		Workdir:    c.WorkingDir,
+	}
+
+	for _, p := range c.Ports {
+		container.Ports = append(container.Ports, UserContainerPort{
+			HostPort:      int(p.HostPort),
+			ContainerPort: int(p.ContainerPort),
+			Protocol:      string(p.Protocol),
+		})
+	}
+
+	for _, e := range c.Env {
+		container.Envs = append(container.Envs, UserEnvironmentVar{Env: e.Name, Value: e.Value})
+	}
+
+	for _, m := range c.VolumeMounts {
+		container.Volumes = append(container.Volumes, UserVolumeReference{
+			Path:     m.MountPath,
+			Volume:   m.Name,
+			ReadOnly: m.ReadOnly,
+		})
+	}
+
+	container.Resources = kubeResourceRequirementsToUserResource(c.Resources)
+
+	return container, nil
+}
+
+// kubeResourceRequirementsToUserResource maps a v1.ResourceRequirements to a
+// UserResource, keeping each Quantity's canonical string form (e.g. "500m",
+// "128Mi") so it round-trips cleanly through GenerateKubeObject.
+func kubeResourceRequirementsToUserResource(r v1.ResourceRequirements) UserResource {
+	var res UserResource
+	if cpu, ok := r.Requests[v1.ResourceCPU]; ok {
+		res.CPU.Requests = cpu.String()
+	}
+	if cpu, ok := r.Limits[v1.ResourceCPU]; ok {
+		res.CPU.Limits = cpu.String()
+	}
+	if mem, ok := r.Requests[v1.ResourceMemory]; ok {
+		res.Memory.Requests = mem.String()
+	}
+	if mem, ok := r.Limits[v1.ResourceMemory]; ok {
+		res.Memory.Limits = mem.String()
+	}
+	if hp, ok := r.Requests[v1.ResourceName("hugepages-2Mi")]; ok {
+		res.HugePages2Mi = hp.String()
+	}
+	if hp, ok := r.Requests[v1.ResourceName("hugepages-1Gi")]; ok {
+		res.HugePages1Gi = hp.String()
+	}
+	if es, ok := r.Requests[v1.ResourceEphemeralStorage]; ok {
+		res.EphemeralStorage = es.String()
+	}
+	return res
+}
+
+// GenerateKubeYAML renders pod as a Kubernetes v1.Pod manifest in YAML,
+// reversing ProcessKubePodBytes. This gives users a portable way to move
+// workloads defined in hyper into any Kubernetes-compatible tool.
+func (pod *UserPod) GenerateKubeYAML() ([]byte, error) {
+	return kubeyaml.Marshal(pod.GenerateKubeObject())
+}
+
+// GenerateKubeObject builds the Kubernetes v1.Pod representation of pod.
+func (pod *UserPod) GenerateKubeObject() *v1.Pod {
+	kubePod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: pod.Name},
+		Spec: v1.PodSpec{
+			Volumes: userVolumesToKubeVolumes(pod.Volumes, pod.Files),
+		},
+	}
+
+	fileByName := make(map[string]UserFile, len(pod.Files))
+	for _, f := range pod.Files {
+		fileByName[f.Name] = f
+	}
+
+	for _, c := range pod.Containers {
+		kubePod.Spec.Containers = append(kubePod.Spec.Containers, userContainerToKubeContainer(c, pod.Resource, len(pod.Containers), fileByName))
+	}
+
+	return kubePod
+}
+
+// userContainerToKubeContainer maps a UserContainer back to a v1.Container.
+// If the container has no resources of its own, the pod's total is split
+// evenly across its containers, since UserPod.Resource predates per
+// container overrides.
+func userContainerToKubeContainer(c UserContainer, podResource UserResource, numContainers int, fileByName map[string]UserFile) v1.Container {
+	kc := v1.Container{
+		Name:       c.Name,
+		Image:      c.Image,
+		Command:    c.Entrypoint,
+		Args:       c.Command,
+		WorkingDir: c.Workdir,
+		Resources:  userResourceToKubeResourceRequirements(effectiveResource(c.Resources, podResource, numContainers)),
+	}
+
+	for _, p := range c.Ports {
+		kc.Ports = append(kc.Ports, v1.ContainerPort{
+			HostPort:      int32(p.HostPort),
+			ContainerPort: int32(p.ContainerPort),
+			Protocol:      v1.Protocol(p.Protocol),
+		})
+	}
+
+	for _, e := range c.Envs {
+		kc.Env = append(kc.Env, v1.EnvVar{Name: e.Env, Value: e.Value})
+	}
+
+	for _, v := range c.Volumes {
+		kc.VolumeMounts = append(kc.VolumeMounts, v1.VolumeMount{Name: v.Volume, MountPath: v.Path, ReadOnly: v.ReadOnly})
+	}
+
+	seenMounts := make(map[string]bool)
+	for _, f := range c.Files {
+		src, ok := fileByName[f.Filename]
+		if !ok || src.SourceRef == nil {
+			continue
+		}
+		mountDir := filepath.Dir(f.Path)
+		if seenMounts[src.SourceRef.Name+"|"+mountDir] {
+			continue
+		}
+		seenMounts[src.SourceRef.Name+"|"+mountDir] = true
+		kc.VolumeMounts = append(kc.VolumeMounts, v1.VolumeMount{Name: src.SourceRef.Name, MountPath: mountDir})
+	}
+
+	return kc
+}
+
+// effectiveResource returns a container's own Resources when set, otherwise
+// an even split of the pod-level total.
+func effectiveResource(container, pod UserResource, numContainers int) UserResource {
+	if container.CPU.Requests != "" || container.CPU.Limits != "" ||
+		container.Memory.Requests != "" || container.Memory.Limits != "" {
+		return container
+	}
+	if numContainers == 0 {
+		return UserResource{}
+	}
+
+	cpu, _ := pod.CPU.requestMillicores()
+	mem, _ := pod.Memory.requestBytes()
+	var split UserResource
+	if cpu > 0 {
+		each := fmt.Sprintf("%dm", cpu/int64(numContainers))
+		split.CPU = ResourceCPUSpec{Requests: each, Limits: each}
+	}
+	if mem > 0 {
+		each := strconv.FormatInt(mem/int64(numContainers), 10)
+		split.Memory = ResourceMemorySpec{Requests: each, Limits: each}
+	}
+	return split
+}
+
+// userResourceToKubeResourceRequirements maps a UserResource to a
+// v1.ResourceRequirements, parsing each quantity with the same Kubernetes
+// quantity syntax it was written in.
+func userResourceToKubeResourceRequirements(r UserResource) v1.ResourceRequirements {
+	reqs, lims := v1.ResourceList{}, v1.ResourceList{}
+
+	addQuantity := func(list v1.ResourceList, name v1.ResourceName, s string) {
+		if s == "" {
+			return
+		}
+		if q, err := resource.ParseQuantity(s); err == nil {
+			list[name] = q
+		}
+	}
+
+	addQuantity(reqs, v1.ResourceCPU, r.CPU.Requests)
+	addQuantity(lims, v1.ResourceCPU, r.CPU.Limits)
+	addQuantity(reqs, v1.ResourceMemory, r.Memory.Requests)
+	addQuantity(lims, v1.ResourceMemory, r.Memory.Limits)
+	addQuantity(reqs, v1.ResourceName("hugepages-2Mi"), r.HugePages2Mi)
+	addQuantity(lims, v1.ResourceName("hugepages-2Mi"), r.HugePages2Mi)
+	addQuantity(reqs, v1.ResourceName("hugepages-1Gi"), r.HugePages1Gi)
+	addQuantity(lims, v1.ResourceName("hugepages-1Gi"), r.HugePages1Gi)
+	addQuantity(reqs, v1.ResourceEphemeralStorage, r.EphemeralStorage)
+	addQuantity(lims, v1.ResourceEphemeralStorage, r.EphemeralStorage)
+
+	if len(reqs) == 0 {
+		reqs = nil
+	}
+	if len(lims) == 0 {
+		lims = nil
+	}
+	return v1.ResourceRequirements{Requests: reqs, Limits: lims}
+}
+
+// userItemsToKubeKeyToPaths maps a configMap/secret UserVolume's Items back
+// to the Kubernetes form; a nil slice (project every key) round-trips as nil.
+func userItemsToKubeKeyToPaths(items []VolumeKeyToPath) []v1.KeyToPath {
+	var out []v1.KeyToPath
+	for _, item := range items {
+		out = append(out, v1.KeyToPath{Key: item.Key, Path: item.Path})
+	}
+	return out
+}
+
+// userVolumesToKubeVolumes maps UserVolume entries, plus any configMap/secret
+// UserFile entries sourced directly via UserFileSourceRef (hyper's native
+// per-key file sourcing, as opposed to a whole UserVolume.ConfigMap/Secret
+// mount), back to v1.Volume entries.
+func userVolumesToKubeVolumes(volumes []UserVolume, files []UserFile) []v1.Volume {
+	var kubeVolumes []v1.Volume
+
+	for _, v := range volumes {
+		v.normalize()
+		kv := v1.Volume{Name: v.Name}
+		switch {
+		case v.HostPath != nil:
+			kv.VolumeSource = v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{Path: v.HostPath.Path}}
+		case v.EmptyDir != nil:
+			medium := v1.StorageMediumDefault
+			if v.EmptyDir.Medium == "Memory" {
+				medium = v1.StorageMediumMemory
+			}
+			kv.VolumeSource = v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{Medium: medium}}
+		case v.NFS != nil:
+			kv.VolumeSource = v1.VolumeSource{NFS: &v1.NFSVolumeSource{
+				Server: v.NFS.Server, Path: v.NFS.Path, ReadOnly: v.NFS.ReadOnly,
+			}}
+		case v.PersistentVolumeClaim != nil:
+			kv.VolumeSource = v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+				ClaimName: v.PersistentVolumeClaim.ClaimName, ReadOnly: v.PersistentVolumeClaim.ReadOnly,
+			}}
+		case v.ISCSI != nil:
+			kv.VolumeSource = v1.VolumeSource{ISCSI: &v1.ISCSIVolumeSource{
+				TargetPortal: v.ISCSI.TargetPortal, IQN: v.ISCSI.Iqn, Lun: int32(v.ISCSI.Lun),
+				FSType: v.ISCSI.FSType, ReadOnly: v.ISCSI.ReadOnly,
+			}}
+		case v.RBD != nil:
+			kv.VolumeSource = v1.VolumeSource{RBD: &v1.RBDVolumeSource{
+				CephMonitors: v.RBD.CephMonitors, RBDImage: v.RBD.RBDImage, RBDPool: v.RBD.RBDPool,
+				RadosUser: v.RBD.RadosUser, Keyring: v.RBD.Keyring, FSType: v.RBD.FSType, ReadOnly: v.RBD.ReadOnly,
+			}}
+		case v.CephFS != nil:
+			kv.VolumeSource = v1.VolumeSource{CephFS: &v1.CephFSVolumeSource{
+				Monitors: v.CephFS.Monitors, Path: v.CephFS.Path, User: v.CephFS.User,
+				SecretFile: v.CephFS.SecretFile, ReadOnly: v.CephFS.ReadOnly,
+			}}
+		case v.ConfigMap != nil:
+			kv.VolumeSource = v1.VolumeSource{ConfigMap: &v1.ConfigMapVolumeSource{
+				LocalObjectReference: v1.LocalObjectReference{Name: v.ConfigMap.Name},
+				Items:                userItemsToKubeKeyToPaths(v.ConfigMap.Items),
+			}}
+		case v.Secret != nil:
+			kv.VolumeSource = v1.VolumeSource{Secret: &v1.SecretVolumeSource{
+				SecretName: v.Secret.SecretName,
+				Items:      userItemsToKubeKeyToPaths(v.Secret.Items),
+			}}
+		default:
+			kv.VolumeSource = v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{Path: v.Source}}
+		}
+		kubeVolumes = append(kubeVolumes, kv)
+	}
+
+	configMaps := make(map[string]*v1.Volume)
+	secrets := make(map[string]*v1.Volume)
+	for _, f := range files {
+		if f.SourceRef == nil {
+			continue
+		}
+		ref := f.SourceRef
+		switch ref.Kind {
+		case "configMap":
+			kv, found := configMaps[ref.Name]
+			if !found {
+				kv = &v1.Volume{Name: ref.Name, VolumeSource: v1.VolumeSource{ConfigMap: &v1.ConfigMapVolumeSource{}}}
+				kv.ConfigMap.Name = ref.Name
+				configMaps[ref.Name] = kv
+				kubeVolumes = append(kubeVolumes, *kv)
+			}
+			kv.ConfigMap.Items = append(kv.ConfigMap.Items, v1.KeyToPath{Key: ref.Key, Path: ref.Key})
+		case "secret":
+			kv, found := secrets[ref.Name]
+			if !found {
+				kv = &v1.Volume{Name: ref.Name, VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: ref.Name}}}
+				secrets[ref.Name] = kv
+				kubeVolumes = append(kubeVolumes, *kv)
+			}
+			kv.Secret.Items = append(kv.Secret.Items, v1.KeyToPath{Key: ref.Key, Path: ref.Key})
+		}
+	}
+
+	return kubeVolumes
+}
@@ -0,0 +1,45 @@
+package pod
+
+import "testing"
+
+func TestGenerateKubeObjectRoundTrip(t *testing.T) {
+	userPod, err := ProcessKubePodBytes([]byte(kubePodManifest))
+	if err != nil {
+		t.Fatalf("ProcessKubePodBytes: %v", err)
+	}
+
+	kubePod := userPod.GenerateKubeObject()
+
+	if kubePod.ObjectMeta.Name != userPod.Name {
+		t.Errorf("GenerateKubeObject name = %q, want %q", kubePod.ObjectMeta.Name, userPod.Name)
+	}
+	if len(kubePod.Spec.Containers) != len(userPod.Containers) {
+		t.Fatalf("got %d kube containers, want %d", len(kubePod.Spec.Containers), len(userPod.Containers))
+	}
+
+	kc := kubePod.Spec.Containers[0]
+	uc := userPod.Containers[0]
+	if len(kc.Command) != 1 || kc.Command[0] != uc.Entrypoint[0] {
+		t.Errorf("Command = %v, want Entrypoint %v to map back to Command", kc.Command, uc.Entrypoint)
+	}
+	if len(kc.Args) != 1 || kc.Args[0] != uc.Command[0] {
+		t.Errorf("Args = %v, want Command %v to map back to Args", kc.Args, uc.Command)
+	}
+
+	var sawConfigMapVolume bool
+	for _, v := range kubePod.Spec.Volumes {
+		if v.Name == "cfg" {
+			sawConfigMapVolume = true
+			if v.ConfigMap == nil || v.ConfigMap.Name != "my-config" {
+				t.Errorf("volume %q: ConfigMap = %+v, want Name my-config", v.Name, v.ConfigMap)
+			}
+		}
+	}
+	if !sawConfigMapVolume {
+		t.Error("configMap volume was dropped on export")
+	}
+
+	if _, err := userPod.GenerateKubeYAML(); err != nil {
+		t.Errorf("GenerateKubeYAML: %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package pod
+
+import "testing"
+
+const kubePodManifest = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - name: app
+    image: nginx:1.17
+    command: ["/entry.sh"]
+    args: ["serve"]
+    workingDir: /app
+    env:
+    - name: FOO
+      value: bar
+    ports:
+    - containerPort: 80
+    volumeMounts:
+    - name: data
+      mountPath: /data
+    - name: cfg
+      mountPath: /etc/cfg
+    resources:
+      requests:
+        cpu: 500m
+        memory: 128Mi
+  volumes:
+  - name: data
+    hostPath:
+      path: /srv/data
+  - name: cfg
+    configMap:
+      name: my-config
+`
+
+func TestProcessKubePodBytesImport(t *testing.T) {
+	userPod, err := ProcessKubePodBytes([]byte(kubePodManifest))
+	if err != nil {
+		t.Fatalf("ProcessKubePodBytes: %v", err)
+	}
+
+	if userPod.Name != "web" {
+		t.Errorf("Name = %q, want %q", userPod.Name, "web")
+	}
+	if len(userPod.Containers) != 1 {
+		t.Fatalf("got %d containers, want 1", len(userPod.Containers))
+	}
+
+	c := userPod.Containers[0]
+	if len(c.Entrypoint) != 1 || c.Entrypoint[0] != "/entry.sh" {
+		t.Errorf("Entrypoint = %v, want command to map to Entrypoint", c.Entrypoint)
+	}
+	if len(c.Command) != 1 || c.Command[0] != "serve" {
+		t.Errorf("Command = %v, want args to map to Command", c.Command)
+	}
+	if c.Resources.CPU.Requests != "500m" {
+		t.Errorf("CPU.Requests = %q, want %q", c.Resources.CPU.Requests, "500m")
+	}
+
+	if len(userPod.Volumes) != 2 {
+		t.Fatalf("got %d volumes, want 2 (hostPath + configMap)", len(userPod.Volumes))
+	}
+
+	var sawConfigMap bool
+	for _, v := range userPod.Volumes {
+		if v.Name == "cfg" {
+			sawConfigMap = true
+			if v.ConfigMap == nil || v.ConfigMap.Name != "my-config" {
+				t.Errorf("volume %q: ConfigMap = %+v, want Name my-config", v.Name, v.ConfigMap)
+			}
+			if len(v.ConfigMap.Items) != 0 {
+				t.Errorf("volume %q: Items = %v, want none (project every key)", v.Name, v.ConfigMap.Items)
+			}
+		}
+	}
+	if !sawConfigMap {
+		t.Error("configMap volume was dropped during import")
+	}
+
+	var sawConfigMapMount bool
+	for _, ref := range c.Volumes {
+		if ref.Volume == "cfg" {
+			sawConfigMapMount = true
+		}
+	}
+	if !sawConfigMapMount {
+		t.Error("container is missing its mount of the configMap volume")
+	}
+}
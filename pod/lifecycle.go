@@ -0,0 +1,146 @@
+package pod
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// ExecAction runs a command inside the container.
+type ExecAction struct {
+	Command []string `json:"command,omitempty"`
+}
+
+// HTTPGetAction performs an HTTP GET against the container.
+type HTTPGetAction struct {
+	Path   string `json:"path,omitempty"`
+	Port   int    `json:"port"`
+	Host   string `json:"host,omitempty"`
+	Scheme string `json:"scheme,omitempty"` // "HTTP" (default) or "HTTPS"
+}
+
+// TCPSocketAction probes that a TCP port accepts connections.
+type TCPSocketAction struct {
+	Port int `json:"port"`
+}
+
+// Handler is exactly one of Exec, HTTPGet or TCPSocket -- the action to run
+// for a probe or a lifecycle hook.
+type Handler struct {
+	Exec      *ExecAction      `json:"exec,omitempty"`
+	HTTPGet   *HTTPGetAction   `json:"httpGet,omitempty"`
+	TCPSocket *TCPSocketAction `json:"tcpSocket,omitempty"`
+}
+
+// handlerCount returns how many of Exec/HTTPGet/TCPSocket are set.
+func (h *Handler) handlerCount() int {
+	n := 0
+	for _, set := range []bool{h.Exec != nil, h.HTTPGet != nil, h.TCPSocket != nil} {
+		if set {
+			n++
+		}
+	}
+	return n
+}
+
+// Probe is a liveness, readiness or startup check for a container.
+type Probe struct {
+	Handler
+
+	InitialDelaySeconds int `json:"initialDelaySeconds,omitempty"`
+	PeriodSeconds       int `json:"periodSeconds,omitempty"`
+	TimeoutSeconds      int `json:"timeoutSeconds,omitempty"`
+	FailureThreshold    int `json:"failureThreshold,omitempty"`
+}
+
+// validate checks that a probe names exactly one handler and carries only
+// non-negative timings.
+func (p *Probe) validate(name string) error {
+	if p.handlerCount() != 1 {
+		return fmt.Errorf("hyper: %s must set exactly one of exec, httpGet or tcpSocket", name)
+	}
+	for field, v := range map[string]int{
+		"initialDelaySeconds": p.InitialDelaySeconds,
+		"periodSeconds":       p.PeriodSeconds,
+		"timeoutSeconds":      p.TimeoutSeconds,
+		"failureThreshold":    p.FailureThreshold,
+	} {
+		if v < 0 {
+			return fmt.Errorf("hyper: %s.%s must not be negative", name, field)
+		}
+	}
+	return nil
+}
+
+// Lifecycle describes actions the runtime should take around a container's
+// start and stop.
+type Lifecycle struct {
+	PostStart *Handler `json:"postStart,omitempty"`
+	PreStop   *Handler `json:"preStop,omitempty"`
+}
+
+// validate checks that any configured hook names exactly one handler.
+func (l *Lifecycle) validate() error {
+	if l.PostStart != nil && l.PostStart.handlerCount() != 1 {
+		return fmt.Errorf("hyper: lifecycle.postStart must set exactly one of exec, httpGet or tcpSocket")
+	}
+	if l.PreStop != nil && l.PreStop.handlerCount() != 1 {
+		return fmt.Errorf("hyper: lifecycle.preStop must set exactly one of exec, httpGet or tcpSocket")
+	}
+	return nil
+}
+
+var restartPolicyOnFailureReg = regexp.MustCompile(`^on-failure:([0-9]+)$`)
+
+// validateRestartPolicy checks that policy is "always", "never", "on-failure"
+// or "on-failure:N" for a non-negative retry count N. An empty policy is left
+// for the caller to default.
+func validateRestartPolicy(policy string) error {
+	if policy == "" || policy == "always" || policy == "never" || policy == "on-failure" {
+		return nil
+	}
+	if restartPolicyOnFailureReg.MatchString(policy) {
+		return nil
+	}
+	return fmt.Errorf("hyper: restartPolicy %q must be one of \"always\", \"never\", \"on-failure\" or \"on-failure:N\"", policy)
+}
+
+// onFailureMaxRetry returns the retry count N of an "on-failure:N" restart
+// policy, or 0 if policy doesn't carry one.
+func onFailureMaxRetry(policy string) int {
+	m := restartPolicyOnFailureReg.FindStringSubmatch(policy)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
+
+// validateLifecycle runs all the container-level probe and restart-policy
+// checks new to this file.
+func (c *UserContainer) validateLifecycle(idx int) error {
+	if err := validateRestartPolicy(c.RestartPolicy); err != nil {
+		return fmt.Errorf("in container %d: %v", idx, err)
+	}
+
+	for name, p := range map[string]*Probe{
+		"livenessProbe":  c.LivenessProbe,
+		"readinessProbe": c.ReadinessProbe,
+		"startupProbe":   c.StartupProbe,
+	} {
+		if p == nil {
+			continue
+		}
+		if err := p.validate(name); err != nil {
+			return fmt.Errorf("in container %d: %v", idx, err)
+		}
+	}
+
+	if c.Lifecycle != nil {
+		if err := c.Lifecycle.validate(); err != nil {
+			return fmt.Errorf("in container %d: %v", idx, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package pod
+
+import "testing"
+
+func TestValidateRestartPolicy(t *testing.T) {
+	cases := []struct {
+		policy  string
+		wantErr bool
+	}{
+		{"", false},
+		{"always", false},
+		{"never", false},
+		{"on-failure", false},
+		{"on-failure:3", false},
+		{"on-failure:", true},
+		{"on-failure:abc", true},
+		{"sometimes", true},
+	}
+	for _, c := range cases {
+		if err := validateRestartPolicy(c.policy); (err != nil) != c.wantErr {
+			t.Errorf("validateRestartPolicy(%q) = %v, wantErr %v", c.policy, err, c.wantErr)
+		}
+	}
+}
+
+func TestOnFailureMaxRetry(t *testing.T) {
+	cases := []struct {
+		policy string
+		want   int
+	}{
+		{"on-failure:5", 5},
+		{"on-failure:0", 0},
+		{"on-failure", 0},
+		{"always", 0},
+		{"", 0},
+	}
+	for _, c := range cases {
+		if got := onFailureMaxRetry(c.policy); got != c.want {
+			t.Errorf("onFailureMaxRetry(%q) = %d, want %d", c.policy, got, c.want)
+		}
+	}
+}
+
+func TestProbeValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		probe   Probe
+		wantErr bool
+	}{
+		{"no handler", Probe{}, true},
+		{
+			"two handlers",
+			Probe{Handler: Handler{Exec: &ExecAction{Command: []string{"true"}}, TCPSocket: &TCPSocketAction{Port: 80}}},
+			true,
+		},
+		{
+			"exec handler ok",
+			Probe{Handler: Handler{Exec: &ExecAction{Command: []string{"true"}}}},
+			false,
+		},
+		{
+			"httpGet handler ok",
+			Probe{Handler: Handler{HTTPGet: &HTTPGetAction{Path: "/healthz", Port: 8080}}},
+			false,
+		},
+		{
+			"negative timing",
+			Probe{Handler: Handler{TCPSocket: &TCPSocketAction{Port: 80}}, InitialDelaySeconds: -1},
+			true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := c.probe.validate("testProbe"); (err != nil) != c.wantErr {
+				t.Errorf("validate() = %v, wantErr %v", err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestLifecycleValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		lc      Lifecycle
+		wantErr bool
+	}{
+		{"no hooks", Lifecycle{}, false},
+		{"valid postStart", Lifecycle{PostStart: &Handler{Exec: &ExecAction{Command: []string{"true"}}}}, false},
+		{"postStart with no handler", Lifecycle{PostStart: &Handler{}}, true},
+		{
+			"preStop with two handlers",
+			Lifecycle{PreStop: &Handler{Exec: &ExecAction{Command: []string{"true"}}, HTTPGet: &HTTPGetAction{Port: 80}}},
+			true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := c.lc.validate(); (err != nil) != c.wantErr {
+				t.Errorf("validate() = %v, wantErr %v", err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserContainerValidateLifecycle(t *testing.T) {
+	c := UserContainer{RestartPolicy: "on-failure:3"}
+	if err := c.validateLifecycle(0); err != nil {
+		t.Errorf("validateLifecycle() = %v, want nil", err)
+	}
+
+	c.RestartPolicy = "sometimes"
+	if err := c.validateLifecycle(0); err == nil {
+		t.Error("validateLifecycle() = nil, want error for invalid restartPolicy")
+	}
+
+	c = UserContainer{LivenessProbe: &Probe{}}
+	if err := c.validateLifecycle(1); err == nil {
+		t.Error("validateLifecycle() = nil, want error for a probe with no handler")
+	}
+
+	c = UserContainer{Lifecycle: &Lifecycle{PreStop: &Handler{}}}
+	if err := c.validateLifecycle(2); err == nil {
+		t.Error("validateLifecycle() = nil, want error for a lifecycle hook with no handler")
+	}
+}
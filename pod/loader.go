@@ -0,0 +1,142 @@
+package pod
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// Loader transcodes the raw bytes for a pod-file format or location into
+// hyper's native POD JSON, ready for ProcessPodBytes.
+type Loader func([]byte) ([]byte, error)
+
+var loaders = map[string]Loader{}
+
+// RegisterLoader registers fn as the loader for schemeOrExt, a file
+// extension (without the leading dot, e.g. "yaml") or a URI scheme (e.g.
+// "https"). It lets downstream tools plug in new pod-file formats without
+// patching this package. Registering under an existing key replaces it.
+func RegisterLoader(schemeOrExt string, fn Loader) {
+	loaders[strings.ToLower(schemeOrExt)] = fn
+}
+
+func init() {
+	RegisterLoader("json", func(body []byte) ([]byte, error) { return body, nil })
+	RegisterLoader("yaml", sigsyaml.YAMLToJSON)
+	RegisterLoader("yml", sigsyaml.YAMLToJSON)
+	RegisterLoader("hcl", hclToJSON)
+	RegisterLoader("http", httpLoader)
+	RegisterLoader("https", httpLoader)
+	RegisterLoader("-", stdinLoader)
+}
+
+// ProcessPodFile reads the POD file at location -- a local path, a "pod.yaml"
+// or "pod.hcl" file, an http(s):// URL, or "-" for stdin -- and translates
+// it into a UserPod. The format is picked by RegisterLoader based on the
+// location's extension or URI scheme; unrecognized ones are assumed to
+// already be hyper's native JSON.
+func ProcessPodFile(location string) (*UserPod, error) {
+	body, err := loadPodFileBytes(location)
+	if err != nil {
+		return nil, err
+	}
+	return ProcessPodBytes(body)
+}
+
+func loadPodFileBytes(location string) ([]byte, error) {
+	if location == "-" {
+		return runLoader("-", nil)
+	}
+
+	if u, err := url.Parse(location); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		return runLoader(u.Scheme, []byte(location))
+	}
+
+	if _, err := os.Stat(location); err != nil && os.IsNotExist(err) {
+		return nil, err
+	}
+	body, err := ioutil.ReadFile(location)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(location)), ".")
+	return runLoader(ext, body)
+}
+
+// runLoader runs the loader registered for key, if any; an unregistered key
+// is assumed to already be hyper's native JSON.
+func runLoader(key string, body []byte) ([]byte, error) {
+	fn, ok := loaders[key]
+	if !ok {
+		return body, nil
+	}
+	return fn(body)
+}
+
+// hclToJSON transcodes an HCL pod file into JSON, keeping today's struct
+// tags as the canonical field names.
+func hclToJSON(body []byte) ([]byte, error) {
+	var raw interface{}
+	if err := hcl.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+	return json.Marshal(raw)
+}
+
+// stdinLoader reads the POD file body from stdin, for "-" locations.
+func stdinLoader(_ []byte) ([]byte, error) {
+	return ioutil.ReadAll(os.Stdin)
+}
+
+// httpLoader fetches a POD file over HTTP(S). A "?sha256=..." query
+// parameter, if present, is stripped from the URL before the fetch and then
+// checked against the downloaded body; the URL path's extension (if any) is
+// used to pick a format loader.
+func httpLoader(locationBytes []byte) ([]byte, error) {
+	location := string(locationBytes)
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	expectedSum := query.Get("sha256")
+	query.Del("sha256")
+	u.RawQuery = query.Encode()
+
+	resp, err := http.Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("hyper: fetching pod file %s: unexpected status %s", location, resp.Status)
+	}
+
+	if expectedSum != "" {
+		sum := sha256.Sum256(body)
+		if hex.EncodeToString(sum[:]) != strings.ToLower(expectedSum) {
+			return nil, fmt.Errorf("hyper: pod file %s failed sha256 checksum verification", location)
+		}
+	}
+
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(u.Path)), ".")
+	return runLoader(ext, body)
+}
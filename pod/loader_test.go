@@ -0,0 +1,66 @@
+package pod
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRunLoaderJSON(t *testing.T) {
+	body := []byte(`{"id":"x"}`)
+	got, err := runLoader("json", body)
+	if err != nil {
+		t.Fatalf("runLoader(json): %v", err)
+	}
+	if !bytes.Equal(got, body) {
+		t.Errorf("runLoader(json) = %q, want passthrough %q", got, body)
+	}
+}
+
+func TestRunLoaderYAML(t *testing.T) {
+	yaml := []byte("id: x\ntype: pod\n")
+	got, err := runLoader("yaml", yaml)
+	if err != nil {
+		t.Fatalf("runLoader(yaml): %v", err)
+	}
+	if !bytes.Contains(got, []byte(`"id":"x"`)) {
+		t.Errorf("runLoader(yaml) = %s, want JSON containing id:x", got)
+	}
+}
+
+func TestRunLoaderUnregisteredKeyPassesThrough(t *testing.T) {
+	body := []byte(`{"id":"x"}`)
+	got, err := runLoader("nope", body)
+	if err != nil {
+		t.Fatalf("runLoader(nope): %v", err)
+	}
+	if !bytes.Equal(got, body) {
+		t.Errorf("runLoader(nope) = %q, want passthrough %q", got, body)
+	}
+}
+
+func TestRegisterLoaderOverridesExisting(t *testing.T) {
+	defer RegisterLoader("json", loaders["json"])
+
+	RegisterLoader("json", func([]byte) ([]byte, error) {
+		return []byte(`{"overridden":true}`), nil
+	})
+
+	got, err := runLoader("json", []byte(`{"id":"x"}`))
+	if err != nil {
+		t.Fatalf("runLoader(json): %v", err)
+	}
+	if !bytes.Equal(got, []byte(`{"overridden":true}`)) {
+		t.Errorf("runLoader(json) = %s, want the overridden loader's output", got)
+	}
+}
+
+func TestHCLToJSON(t *testing.T) {
+	hcl := []byte(`id = "x"` + "\n" + `type = "pod"`)
+	got, err := hclToJSON(hcl)
+	if err != nil {
+		t.Fatalf("hclToJSON: %v", err)
+	}
+	if !bytes.Contains(got, []byte(`"id":"x"`)) {
+		t.Errorf("hclToJSON = %s, want JSON containing id:x", got)
+	}
+}
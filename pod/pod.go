@@ -5,10 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
-	"os"
 	"reflect"
-	"regexp"
+	"strconv"
 )
 
 // Pod Data Structure
@@ -49,45 +47,31 @@ type UserContainer struct {
 	Volumes       []UserVolumeReference `json:"volumes"`
 	Files         []UserFileReference   `json:"files"`
 	RestartPolicy string                `json:"restartPolicy"`
-}
+	Resources     UserResource          `json:"resources,omitempty"`
 
-type UserResource struct {
-	Vcpu   int `json:"vcpu"`
-	Memory int `json:"memory"`
+	LivenessProbe  *Probe     `json:"livenessProbe,omitempty"`
+	ReadinessProbe *Probe     `json:"readinessProbe,omitempty"`
+	StartupProbe   *Probe     `json:"startupProbe,omitempty"`
+	Lifecycle      *Lifecycle `json:"lifecycle,omitempty"`
 }
 
 type UserFile struct {
-	Name     string `json:"name"`
-	Encoding string `json:"encoding"`
-	Uri      string `json:"uri"`
-	Contents string `json:"content"`
-}
-
-type UserVolume struct {
-	Name   string `json:"name"`
-	Source string `json:"source"`
-	Driver string `json:"driver"`
+	Name      string             `json:"name"`
+	Encoding  string             `json:"encoding"`
+	Uri       string             `json:"uri"`
+	Contents  string             `json:"content"`
+	SourceRef *UserFileSourceRef `json:"sourceRef,omitempty"`
 }
 
 type UserPod struct {
-	Name       string          `json:"id"`
-	Containers []UserContainer `json:"containers"`
-	Resource   UserResource    `json:"resource"`
-	Files      []UserFile      `json:"files"`
-	Volumes    []UserVolume    `json:"volumes"`
-	Tty        bool            `json:"tty"`
-	Type       string          `json:"type"`
-}
-
-func ProcessPodFile(jsonFile string) (*UserPod, error) {
-	if _, err := os.Stat(jsonFile); err != nil && os.IsNotExist(err) {
-		return nil, err
-	}
-	body, err := ioutil.ReadFile(jsonFile)
-	if err != nil {
-		return nil, err
-	}
-	return ProcessPodBytes(body)
+	Name       string           `json:"id"`
+	Containers []UserContainer  `json:"containers"`
+	Resource   UserResource     `json:"resource"`
+	Files      []UserFile       `json:"files"`
+	Volumes    []UserVolume     `json:"volumes"`
+	Sources    []UserFileSource `json:"sources,omitempty"`
+	Tty        bool             `json:"tty"`
+	Type       string           `json:"type"`
 }
 
 func ProcessPodBytes(body []byte) (*UserPod, error) {
@@ -102,12 +86,7 @@ func ProcessPodBytes(body []byte) (*UserPod, error) {
 		userPod.Name = RandStr(10, "alphanum")
 	}
 
-	if userPod.Resource.Vcpu == 0 {
-		userPod.Resource.Vcpu = 1
-	}
-	if userPod.Resource.Memory == 0 {
-		userPod.Resource.Memory = 128
-	}
+	userPod.Resource.setDefaults()
 
 	var (
 		v   UserContainer
@@ -123,10 +102,12 @@ func ProcessPodBytes(body []byte) (*UserPod, error) {
 	if num == 0 {
 		return nil, fmt.Errorf("Please correct your POD file, the container section can not be null!\n")
 	}
-	for _, vol = range userPod.Volumes {
+	for i := range userPod.Volumes {
+		vol = userPod.Volumes[i]
 		if vol.Name == "" {
 			return nil, fmt.Errorf("Hyper ERROR: please specific your volume name, it can not be null!\n")
 		}
+		userPod.Volumes[i].normalize()
 	}
 
 	return &userPod, nil
@@ -167,13 +148,28 @@ func (pod *UserPod) Validate() error {
 		}
 	}
 
+	for i := range pod.Volumes {
+		if err := pod.Volumes[i].validateSource(); err != nil {
+			return err
+		}
+	}
+
 	uniq, fset := keySet(pod.Files)
 	if !uniq {
 		if len(fset) > 0 {
 			return errors.New("Files name does not unique")
 		}
 	}
-	var permReg = regexp.MustCompile("0[0-7]{3}")
+
+	if err := pod.validateSources(); err != nil {
+		return err
+	}
+
+	fileByName := make(map[string]UserFile, len(pod.Files))
+	for _, f := range pod.Files {
+		fileByName[f.Name] = f
+	}
+
 	for idx, container := range pod.Containers {
 
 		if uniq, _ := keySet(container.Volumes); !uniq {
@@ -188,12 +184,10 @@ func (pod *UserPod) Validate() error {
 			if _, ok := fset[f.Filename]; !ok {
 				return fmt.Errorf("in container %d, file %s does not exist in file list.", idx, f.Filename)
 			}
-			if f.Perm == "" {
-				f.Perm = "0755"
-			}
-			if f.Perm != "0" {
-				if !permReg.Match([]byte(f.Perm)) {
-					return fmt.Errorf("in container %d, the permission %s only accept Octal digital in string")
+			perm := filePerm(f, fileByName[f.Filename])
+			if perm != "0" {
+				if _, err := strconv.ParseUint(perm, 8, 32); err != nil {
+					return fmt.Errorf("in container %d, the permission %s only accept Octal digital in string", idx, perm)
 				}
 			}
 		}
@@ -203,6 +197,14 @@ func (pod *UserPod) Validate() error {
 				return fmt.Errorf("in container %d, volume %s does not exist in volume list.", idx, v.Volume)
 			}
 		}
+
+		if err := container.validateLifecycle(idx); err != nil {
+			return err
+		}
+	}
+
+	if err := pod.checkResourceOvercommit(); err != nil {
+		return err
 	}
 
 	return nil
@@ -0,0 +1,217 @@
+package pod
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ResourceCPUSpec describes how much CPU a pod or container asks for
+// (requests) and is capped at (limits). Values are either a fractional
+// number of cores ("0.5", "2") or millicores with an "m" suffix ("500m").
+type ResourceCPUSpec struct {
+	Requests string `json:"requests,omitempty"`
+	Limits   string `json:"limits,omitempty"`
+}
+
+// ResourceMemorySpec describes how much memory a pod or container asks for
+// (requests) and is capped at (limits). Values take an optional binary
+// ("256Mi", "1Gi") or decimal ("1G") unit suffix; a bare number is bytes.
+type ResourceMemorySpec struct {
+	Requests string `json:"requests,omitempty"`
+	Limits   string `json:"limits,omitempty"`
+}
+
+// UserResource is the cpu/memory/hugepages spec for a pod or a single
+// container override. It accepts hyper's original flat {vcpu, memory}
+// shape on read (vcpu in cores, memory in MiB) for backward compatibility,
+// normalizing it into CPU/Memory on the way in.
+type UserResource struct {
+	CPU              ResourceCPUSpec    `json:"cpu,omitempty"`
+	Memory           ResourceMemorySpec `json:"memory,omitempty"`
+	HugePages2Mi     string             `json:"hugepages-2Mi,omitempty"`
+	HugePages1Gi     string             `json:"hugepages-1Gi,omitempty"`
+	EphemeralStorage string             `json:"ephemeral-storage,omitempty"`
+}
+
+// resourceWire is the on-disk shape of UserResource. Memory is decoded as
+// raw JSON because it may be either the legacy bare integer (MiB) or the
+// new {requests, limits} object.
+type resourceWire struct {
+	Vcpu             int              `json:"vcpu"`
+	CPU              *ResourceCPUSpec `json:"cpu"`
+	Memory           json.RawMessage  `json:"memory"`
+	HugePages2Mi     string           `json:"hugepages-2Mi"`
+	HugePages1Gi     string           `json:"hugepages-1Gi"`
+	EphemeralStorage string           `json:"ephemeral-storage"`
+}
+
+func (r *UserResource) UnmarshalJSON(data []byte) error {
+	var w resourceWire
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+
+	r.HugePages2Mi = w.HugePages2Mi
+	r.HugePages1Gi = w.HugePages1Gi
+	r.EphemeralStorage = w.EphemeralStorage
+
+	switch {
+	case w.CPU != nil:
+		r.CPU = *w.CPU
+	case w.Vcpu != 0:
+		r.CPU = ResourceCPUSpec{Requests: strconv.Itoa(w.Vcpu), Limits: strconv.Itoa(w.Vcpu)}
+	}
+
+	if len(w.Memory) == 0 {
+		return nil
+	}
+
+	var legacyMB int
+	if err := json.Unmarshal(w.Memory, &legacyMB); err == nil {
+		r.Memory = ResourceMemorySpec{
+			Requests: strconv.Itoa(legacyMB) + "Mi",
+			Limits:   strconv.Itoa(legacyMB) + "Mi",
+		}
+		return nil
+	}
+
+	var spec ResourceMemorySpec
+	if err := json.Unmarshal(w.Memory, &spec); err != nil {
+		return fmt.Errorf("hyper: memory must be either a MiB integer or a {requests, limits} object: %v", err)
+	}
+	r.Memory = spec
+	return nil
+}
+
+// setDefaults fills in hyper's historical defaults (1 core, 128Mi) when the
+// pod spec leaves cpu/memory unset entirely.
+func (r *UserResource) setDefaults() {
+	if r.CPU.Requests == "" && r.CPU.Limits == "" {
+		r.CPU = ResourceCPUSpec{Requests: "1", Limits: "1"}
+	}
+	if r.Memory.Requests == "" && r.Memory.Limits == "" {
+		r.Memory = ResourceMemorySpec{Requests: "128Mi", Limits: "128Mi"}
+	}
+}
+
+// requestMillicores parses a ResourceCPUSpec's requests, preferring it over
+// limits since that's what scheduling and over-commit checks key off.
+func (r ResourceCPUSpec) requestMillicores() (int64, error) {
+	v := r.Requests
+	if v == "" {
+		v = r.Limits
+	}
+	if v == "" {
+		return 0, nil
+	}
+	return ParseCPUQuantity(v)
+}
+
+// requestBytes parses a ResourceMemorySpec's requests, preferring it over
+// limits since that's what scheduling and over-commit checks key off.
+func (r ResourceMemorySpec) requestBytes() (int64, error) {
+	v := r.Requests
+	if v == "" {
+		v = r.Limits
+	}
+	if v == "" {
+		return 0, nil
+	}
+	return ParseMemoryQuantity(v)
+}
+
+// ParseCPUQuantity parses a CPU quantity expressed either as a fractional
+// number of cores ("0.5", "2") or as millicores ("500m"), returning the
+// value in millicores.
+func ParseCPUQuantity(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if strings.HasSuffix(s, "m") {
+		milli, err := strconv.ParseInt(strings.TrimSuffix(s, "m"), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("hyper: invalid cpu quantity %q: %v", s, err)
+		}
+		return milli, nil
+	}
+	cores, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("hyper: invalid cpu quantity %q: %v", s, err)
+	}
+	return int64(cores * 1000), nil
+}
+
+// binarySuffixes maps the Kubernetes binary/decimal memory unit suffixes to
+// the number of bytes they represent, ordered longest-first so e.g. "Mi" is
+// tried before "M".
+var binarySuffixes = []struct {
+	suffix string
+	factor int64
+}{
+	{"Ei", 1 << 60}, {"Pi", 1 << 50}, {"Ti", 1 << 40}, {"Gi", 1 << 30}, {"Mi", 1 << 20}, {"Ki", 1 << 10},
+	{"E", 1e18}, {"P", 1e15}, {"T", 1e12}, {"G", 1e9}, {"M", 1e6}, {"K", 1e3},
+}
+
+// checkResourceOvercommit rejects pods whose per-container resource
+// requests add up to more than the pod-level totals. Containers that leave
+// Resources unset are not counted, since they draw from the pod's shared
+// budget rather than reserving their own share of it.
+func (pod *UserPod) checkResourceOvercommit() error {
+	podCPU, err := pod.Resource.CPU.requestMillicores()
+	if err != nil {
+		return err
+	}
+	podMem, err := pod.Resource.Memory.requestBytes()
+	if err != nil {
+		return err
+	}
+
+	var sumCPU, sumMem int64
+	for idx, c := range pod.Containers {
+		cpu, err := c.Resources.CPU.requestMillicores()
+		if err != nil {
+			return fmt.Errorf("in container %d: %v", idx, err)
+		}
+		mem, err := c.Resources.Memory.requestBytes()
+		if err != nil {
+			return fmt.Errorf("in container %d: %v", idx, err)
+		}
+		sumCPU += cpu
+		sumMem += mem
+	}
+
+	if podCPU > 0 && sumCPU > podCPU {
+		return fmt.Errorf("hyper: container cpu requests (%dm) exceed pod total (%dm)", sumCPU, podCPU)
+	}
+	if podMem > 0 && sumMem > podMem {
+		return fmt.Errorf("hyper: container memory requests (%d bytes) exceed pod total (%d bytes)", sumMem, podMem)
+	}
+
+	return nil
+}
+
+// ParseMemoryQuantity parses a memory quantity with an optional Kubernetes
+// binary ("Ki", "Mi", "Gi", ...) or decimal ("K", "M", "G", ...) unit
+// suffix, returning the value in bytes. A bare number is interpreted as
+// bytes.
+func ParseMemoryQuantity(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	for _, u := range binarySuffixes {
+		if strings.HasSuffix(s, u.suffix) {
+			n, err := strconv.ParseFloat(strings.TrimSuffix(s, u.suffix), 64)
+			if err != nil {
+				return 0, fmt.Errorf("hyper: invalid memory quantity %q: %v", s, err)
+			}
+			return int64(n * float64(u.factor)), nil
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("hyper: invalid memory quantity %q: %v", s, err)
+	}
+	return n, nil
+}
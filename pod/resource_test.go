@@ -0,0 +1,107 @@
+package pod
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestUserResourceUnmarshalJSONLegacyShape(t *testing.T) {
+	var r UserResource
+	if err := json.Unmarshal([]byte(`{"vcpu":2,"memory":256}`), &r); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if r.CPU.Requests != "2" || r.CPU.Limits != "2" {
+		t.Errorf("CPU = %+v, want Requests/Limits \"2\"", r.CPU)
+	}
+	if r.Memory.Requests != "256Mi" || r.Memory.Limits != "256Mi" {
+		t.Errorf("Memory = %+v, want Requests/Limits \"256Mi\"", r.Memory)
+	}
+}
+
+func TestUserResourceUnmarshalJSONNewShape(t *testing.T) {
+	var r UserResource
+	body := `{"cpu":{"requests":"500m","limits":"1"},"memory":{"requests":"128Mi","limits":"256Mi"}}`
+	if err := json.Unmarshal([]byte(body), &r); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if r.CPU.Requests != "500m" || r.CPU.Limits != "1" {
+		t.Errorf("CPU = %+v, want {500m 1}", r.CPU)
+	}
+	if r.Memory.Requests != "128Mi" || r.Memory.Limits != "256Mi" {
+		t.Errorf("Memory = %+v, want {128Mi 256Mi}", r.Memory)
+	}
+}
+
+func TestParseCPUQuantity(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"500m", 500, false},
+		{"1", 1000, false},
+		{"0.5", 500, false},
+		{"2", 2000, false},
+		{"bogus", 0, true},
+	}
+	for _, c := range cases {
+		got, err := ParseCPUQuantity(c.in)
+		if (err != nil) != c.wantErr {
+			t.Errorf("ParseCPUQuantity(%q) error = %v, wantErr %v", c.in, err, c.wantErr)
+			continue
+		}
+		if err == nil && got != c.want {
+			t.Errorf("ParseCPUQuantity(%q) = %d, want %d", c.in, got, c.want)
+		}
+	}
+}
+
+func TestParseMemoryQuantity(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"128974848", 128974848, false},
+		{"1Ki", 1024, false},
+		{"128Mi", 128 * 1 << 20, false},
+		{"1Gi", 1 << 30, false},
+		{"1G", 1e9, false},
+		{"bogus", 0, true},
+	}
+	for _, c := range cases {
+		got, err := ParseMemoryQuantity(c.in)
+		if (err != nil) != c.wantErr {
+			t.Errorf("ParseMemoryQuantity(%q) error = %v, wantErr %v", c.in, err, c.wantErr)
+			continue
+		}
+		if err == nil && got != c.want {
+			t.Errorf("ParseMemoryQuantity(%q) = %d, want %d", c.in, got, c.want)
+		}
+	}
+}
+
+func TestCheckResourceOvercommit(t *testing.T) {
+	pod := &UserPod{
+		Resource: UserResource{
+			CPU:    ResourceCPUSpec{Requests: "1"},
+			Memory: ResourceMemorySpec{Requests: "128Mi"},
+		},
+		Containers: []UserContainer{
+			{Resources: UserResource{CPU: ResourceCPUSpec{Requests: "600m"}, Memory: ResourceMemorySpec{Requests: "64Mi"}}},
+			{Resources: UserResource{CPU: ResourceCPUSpec{Requests: "600m"}, Memory: ResourceMemorySpec{Requests: "64Mi"}}},
+		},
+	}
+	if err := pod.checkResourceOvercommit(); err == nil {
+		t.Error("checkResourceOvercommit() = nil, want error for 1200m > 1000m pod total")
+	}
+
+	pod.Containers[1].Resources.CPU.Requests = "400m"
+	if err := pod.checkResourceOvercommit(); err != nil {
+		t.Errorf("checkResourceOvercommit() = %v, want nil for requests within pod total", err)
+	}
+}
@@ -0,0 +1,193 @@
+package pod
+
+import "fmt"
+
+// VolumeHostPathSource mounts a file or directory from the host.
+type VolumeHostPathSource struct {
+	Path string `json:"path"`
+}
+
+// VolumeEmptyDirSource is a directory created empty when the pod starts,
+// optionally backed by memory instead of disk.
+type VolumeEmptyDirSource struct {
+	Medium    string `json:"medium,omitempty"` // "Memory", or "" / "Default"
+	SizeLimit string `json:"sizeLimit,omitempty"`
+}
+
+// VolumeNFSSource mounts an NFS export.
+type VolumeNFSSource struct {
+	Server   string `json:"server"`
+	Path     string `json:"path"`
+	ReadOnly bool   `json:"readOnly,omitempty"`
+}
+
+// VolumeISCSISource mounts an iSCSI LUN.
+type VolumeISCSISource struct {
+	TargetPortal string `json:"targetPortal"`
+	Iqn          string `json:"iqn"`
+	Lun          int    `json:"lun"`
+	FSType       string `json:"fsType,omitempty"`
+	ReadOnly     bool   `json:"readOnly,omitempty"`
+}
+
+// VolumeRBDSource mounts a Ceph RBD image.
+type VolumeRBDSource struct {
+	CephMonitors []string `json:"monitors"`
+	RBDImage     string   `json:"image"`
+	RBDPool      string   `json:"pool,omitempty"`
+	RadosUser    string   `json:"user,omitempty"`
+	Keyring      string   `json:"keyring,omitempty"`
+	FSType       string   `json:"fsType,omitempty"`
+	ReadOnly     bool     `json:"readOnly,omitempty"`
+}
+
+// VolumeCephFSSource mounts a CephFS volume.
+type VolumeCephFSSource struct {
+	Monitors   []string `json:"monitors"`
+	Path       string   `json:"path,omitempty"`
+	User       string   `json:"user,omitempty"`
+	SecretFile string   `json:"secretFile,omitempty"`
+	ReadOnly   bool     `json:"readOnly,omitempty"`
+}
+
+// VolumeKeyToPath maps a single key of a configMap or secret to a path
+// inside the volume's mount point; Path defaults to Key when empty.
+type VolumeKeyToPath struct {
+	Key  string `json:"key"`
+	Path string `json:"path,omitempty"`
+}
+
+// VolumeConfigMapSource projects a configMap as a directory of files, one
+// per key, restricted to Items when non-empty.
+type VolumeConfigMapSource struct {
+	Name  string            `json:"name"`
+	Items []VolumeKeyToPath `json:"items,omitempty"`
+}
+
+// VolumeSecretSource projects a secret as a directory of files, one per
+// key, restricted to Items when non-empty.
+type VolumeSecretSource struct {
+	SecretName string            `json:"secretName"`
+	Items      []VolumeKeyToPath `json:"items,omitempty"`
+}
+
+// VolumePersistentVolumeClaimSource binds a previously provisioned claim.
+type VolumePersistentVolumeClaimSource struct {
+	ClaimName string `json:"claimName"`
+	ReadOnly  bool   `json:"readOnly,omitempty"`
+}
+
+// UserVolume describes a volume available to be mounted into containers of
+// a pod. It is a tagged union: exactly one of the typed source fields below
+// should be set. Source and Driver are kept for backward compatibility with
+// the original flat schema ({"name", "source", "driver"}); if none of the
+// typed sources are set, they are interpreted as a persistentVolumeClaim
+// named by Source when Driver is "pvc", and otherwise as a hostPath (or,
+// when Source is empty, an emptyDir).
+type UserVolume struct {
+	Name   string `json:"name"`
+	Source string `json:"source,omitempty"`
+	Driver string `json:"driver,omitempty"`
+
+	HostPath              *VolumeHostPathSource              `json:"hostPath,omitempty"`
+	EmptyDir              *VolumeEmptyDirSource              `json:"emptyDir,omitempty"`
+	NFS                   *VolumeNFSSource                   `json:"nfs,omitempty"`
+	ISCSI                 *VolumeISCSISource                 `json:"iscsi,omitempty"`
+	RBD                   *VolumeRBDSource                   `json:"rbd,omitempty"`
+	CephFS                *VolumeCephFSSource                `json:"cephfs,omitempty"`
+	ConfigMap             *VolumeConfigMapSource             `json:"configMap,omitempty"`
+	Secret                *VolumeSecretSource                `json:"secret,omitempty"`
+	PersistentVolumeClaim *VolumePersistentVolumeClaimSource `json:"persistentVolumeClaim,omitempty"`
+
+	DriverOptions map[string]string `json:"driverOptions,omitempty"`
+}
+
+// typedSourceCount returns how many typed source fields are set, to detect
+// a mistakenly over-specified volume.
+func (vol *UserVolume) typedSourceCount() int {
+	n := 0
+	for _, set := range []bool{
+		vol.HostPath != nil,
+		vol.EmptyDir != nil,
+		vol.NFS != nil,
+		vol.ISCSI != nil,
+		vol.RBD != nil,
+		vol.CephFS != nil,
+		vol.ConfigMap != nil,
+		vol.Secret != nil,
+		vol.PersistentVolumeClaim != nil,
+	} {
+		if set {
+			n++
+		}
+	}
+	return n
+}
+
+// normalize fills in the typed source from the legacy {source, driver} pair
+// when no typed source was given, so the rest of hyper only has to reason
+// about the typed form.
+func (vol *UserVolume) normalize() {
+	if vol.typedSourceCount() > 0 {
+		return
+	}
+	switch vol.Driver {
+	case "pvc":
+		vol.PersistentVolumeClaim = &VolumePersistentVolumeClaimSource{ClaimName: vol.Source}
+	default:
+		if vol.Source == "" {
+			vol.EmptyDir = &VolumeEmptyDirSource{}
+		} else {
+			vol.HostPath = &VolumeHostPathSource{Path: vol.Source}
+		}
+	}
+}
+
+// validateSource checks that the volume's typed source, if any, carries its
+// required fields.
+func (vol *UserVolume) validateSource() error {
+	if vol.typedSourceCount() > 1 {
+		return fmt.Errorf("volume %s: only one volume source may be set", vol.Name)
+	}
+
+	switch {
+	case vol.HostPath != nil:
+		if vol.HostPath.Path == "" {
+			return fmt.Errorf("volume %s: hostPath requires a path", vol.Name)
+		}
+	case vol.EmptyDir != nil:
+		if vol.EmptyDir.Medium != "" && vol.EmptyDir.Medium != "Memory" && vol.EmptyDir.Medium != "Default" {
+			return fmt.Errorf("volume %s: emptyDir medium must be \"Memory\" or \"Default\"", vol.Name)
+		}
+	case vol.NFS != nil:
+		if vol.NFS.Server == "" || vol.NFS.Path == "" {
+			return fmt.Errorf("volume %s: nfs requires server and path", vol.Name)
+		}
+	case vol.ISCSI != nil:
+		if vol.ISCSI.TargetPortal == "" || vol.ISCSI.Iqn == "" {
+			return fmt.Errorf("volume %s: iscsi requires targetPortal and iqn", vol.Name)
+		}
+	case vol.RBD != nil:
+		if len(vol.RBD.CephMonitors) == 0 || vol.RBD.RBDImage == "" {
+			return fmt.Errorf("volume %s: rbd requires monitors and image", vol.Name)
+		}
+	case vol.CephFS != nil:
+		if len(vol.CephFS.Monitors) == 0 {
+			return fmt.Errorf("volume %s: cephfs requires monitors", vol.Name)
+		}
+	case vol.ConfigMap != nil:
+		if vol.ConfigMap.Name == "" {
+			return fmt.Errorf("volume %s: configMap requires a name", vol.Name)
+		}
+	case vol.Secret != nil:
+		if vol.Secret.SecretName == "" {
+			return fmt.Errorf("volume %s: secret requires a secretName", vol.Name)
+		}
+	case vol.PersistentVolumeClaim != nil:
+		if vol.PersistentVolumeClaim.ClaimName == "" {
+			return fmt.Errorf("volume %s: persistentVolumeClaim requires a claimName", vol.Name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package pod
+
+import "testing"
+
+func TestUserVolumeNormalize(t *testing.T) {
+	cases := []struct {
+		name  string
+		vol   UserVolume
+		check func(*testing.T, UserVolume)
+	}{
+		{
+			name: "empty source becomes emptyDir",
+			vol:  UserVolume{Name: "v"},
+			check: func(t *testing.T, got UserVolume) {
+				if got.EmptyDir == nil {
+					t.Error("EmptyDir = nil, want set")
+				}
+			},
+		},
+		{
+			name: "bare source becomes hostPath",
+			vol:  UserVolume{Name: "v", Source: "/srv/data"},
+			check: func(t *testing.T, got UserVolume) {
+				if got.HostPath == nil || got.HostPath.Path != "/srv/data" {
+					t.Errorf("HostPath = %+v, want Path /srv/data", got.HostPath)
+				}
+			},
+		},
+		{
+			name: "pvc driver becomes persistentVolumeClaim",
+			vol:  UserVolume{Name: "v", Source: "my-claim", Driver: "pvc"},
+			check: func(t *testing.T, got UserVolume) {
+				if got.PersistentVolumeClaim == nil || got.PersistentVolumeClaim.ClaimName != "my-claim" {
+					t.Errorf("PersistentVolumeClaim = %+v, want ClaimName my-claim", got.PersistentVolumeClaim)
+				}
+			},
+		},
+		{
+			name: "typed source is left alone",
+			vol:  UserVolume{Name: "v", Source: "/ignored", NFS: &VolumeNFSSource{Server: "s", Path: "/p"}},
+			check: func(t *testing.T, got UserVolume) {
+				if got.HostPath != nil {
+					t.Errorf("HostPath = %+v, want nil since NFS was already set", got.HostPath)
+				}
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			vol := c.vol
+			vol.normalize()
+			c.check(t, vol)
+		})
+	}
+}
+
+func TestUserVolumeTypedSourceCount(t *testing.T) {
+	var vol UserVolume
+	if n := vol.typedSourceCount(); n != 0 {
+		t.Errorf("typedSourceCount() = %d, want 0", n)
+	}
+
+	vol.HostPath = &VolumeHostPathSource{Path: "/x"}
+	if n := vol.typedSourceCount(); n != 1 {
+		t.Errorf("typedSourceCount() = %d, want 1", n)
+	}
+
+	vol.NFS = &VolumeNFSSource{Server: "s", Path: "/p"}
+	if n := vol.typedSourceCount(); n != 2 {
+		t.Errorf("typedSourceCount() = %d, want 2", n)
+	}
+}
+
+func TestUserVolumeValidateSource(t *testing.T) {
+	cases := []struct {
+		name    string
+		vol     UserVolume
+		wantErr bool
+	}{
+		{"no typed source is fine", UserVolume{Name: "v"}, false},
+		{"hostPath with path", UserVolume{Name: "v", HostPath: &VolumeHostPathSource{Path: "/x"}}, false},
+		{"hostPath missing path", UserVolume{Name: "v", HostPath: &VolumeHostPathSource{}}, true},
+		{"emptyDir default medium", UserVolume{Name: "v", EmptyDir: &VolumeEmptyDirSource{}}, false},
+		{"emptyDir bad medium", UserVolume{Name: "v", EmptyDir: &VolumeEmptyDirSource{Medium: "Disk"}}, true},
+		{"nfs complete", UserVolume{Name: "v", NFS: &VolumeNFSSource{Server: "s", Path: "/p"}}, false},
+		{"nfs missing path", UserVolume{Name: "v", NFS: &VolumeNFSSource{Server: "s"}}, true},
+		{"iscsi complete", UserVolume{Name: "v", ISCSI: &VolumeISCSISource{TargetPortal: "p", Iqn: "iqn"}}, false},
+		{"iscsi missing iqn", UserVolume{Name: "v", ISCSI: &VolumeISCSISource{TargetPortal: "p"}}, true},
+		{"rbd complete", UserVolume{Name: "v", RBD: &VolumeRBDSource{CephMonitors: []string{"m1"}, RBDImage: "img"}}, false},
+		{"rbd missing monitors", UserVolume{Name: "v", RBD: &VolumeRBDSource{RBDImage: "img"}}, true},
+		{"cephfs complete", UserVolume{Name: "v", CephFS: &VolumeCephFSSource{Monitors: []string{"m1"}}}, false},
+		{"cephfs missing monitors", UserVolume{Name: "v", CephFS: &VolumeCephFSSource{}}, true},
+		{"configMap complete", UserVolume{Name: "v", ConfigMap: &VolumeConfigMapSource{Name: "cfg"}}, false},
+		{"configMap missing name", UserVolume{Name: "v", ConfigMap: &VolumeConfigMapSource{}}, true},
+		{"secret complete", UserVolume{Name: "v", Secret: &VolumeSecretSource{SecretName: "s"}}, false},
+		{"secret missing name", UserVolume{Name: "v", Secret: &VolumeSecretSource{}}, true},
+		{"pvc complete", UserVolume{Name: "v", PersistentVolumeClaim: &VolumePersistentVolumeClaimSource{ClaimName: "c"}}, false},
+		{"pvc missing claim", UserVolume{Name: "v", PersistentVolumeClaim: &VolumePersistentVolumeClaimSource{}}, true},
+		{
+			"more than one typed source",
+			UserVolume{Name: "v", HostPath: &VolumeHostPathSource{Path: "/x"}, NFS: &VolumeNFSSource{Server: "s", Path: "/p"}},
+			true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.vol.validateSource()
+			if (err != nil) != c.wantErr {
+				t.Errorf("validateSource() = %v, wantErr %v", err, c.wantErr)
+			}
+		})
+	}
+}